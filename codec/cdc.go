@@ -0,0 +1,138 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"math"
+	"sort"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	// cdcWindow is the number of trailing deltas the rolling hash considers when deciding a cut
+	// point, analogous to the window used by rollsum-based chunkers for content-addressed
+	// storage.
+	cdcWindow = 64
+	// cdcPrime is the multiplier of the Rabin-style polynomial rolling hash.
+	cdcPrime uint64 = 1099511628211
+
+	// defaultCDCMask, with 6 bits set, cuts a block at each uid past CDCMin with probability
+	// 1/(1<<6) = 1/64.
+	defaultCDCMask uint64 = 1<<6 - 1
+	defaultCDCMin  uint32 = 16
+	defaultCDCMax  uint32 = 1024
+)
+
+// cdcParams returns the mask/min/max this Encoder will use for CDC boundary cuts, falling back to
+// the package defaults for any field left at its zero value.
+func (e *Encoder) cdcParams() (mask uint64, min, max uint32) {
+	mask, min, max = e.CDCMask, e.CDCMin, e.CDCMax
+	if mask == 0 {
+		mask = defaultCDCMask
+	}
+	if min == 0 {
+		min = defaultCDCMin
+	}
+	if max == 0 {
+		max = defaultCDCMax
+	}
+	return mask, min, max
+}
+
+// cdcChunker maintains a fixed-size rolling window over a stream of uint64 deltas and exposes the
+// polynomial hash of the window's current contents. h_i = (h_{i-1}*P + delta_i) - delta_{i-w}*P^w,
+// all mod 2^64 (via normal uint64 wraparound), which is algebraically the hash of exactly the last
+// w deltas pushed.
+type cdcChunker struct {
+	window [cdcWindow]uint64
+	pos    int
+	hash   uint64
+	primeW uint64 // cdcPrime^cdcWindow
+}
+
+func newCDCChunker() *cdcChunker {
+	c := &cdcChunker{primeW: 1}
+	for i := 0; i < cdcWindow; i++ {
+		c.primeW *= cdcPrime
+	}
+	return c
+}
+
+// push feeds the next delta into the window and returns the updated hash.
+func (c *cdcChunker) push(delta uint64) uint64 {
+	out := c.window[c.pos]
+	c.hash = c.hash*cdcPrime + delta - out*c.primeW
+	c.window[c.pos] = delta
+	c.pos = (c.pos + 1) % cdcWindow
+	return c.hash
+}
+
+// forEachBlockCDC partitions e.uids into variable-size blocks using content-defined boundaries,
+// calling emit once per block: a cut is made once a block has reached CDCMin uids and the rolling
+// hash of the trailing window of deltas matches CDCMask, or once the block hits CDCMax uids.
+// Because the cut decision depends only on a bounded trailing window, inserting or removing a
+// handful of UIDs only perturbs the blocks whose window overlaps the edit, not every block after
+// it.
+func (e *Encoder) forEachBlockCDC(emit func(*pb.UidBlock)) {
+	mask, min, max := e.cdcParams()
+	e.pack.CdcMask, e.pack.CdcMin, e.pack.CdcMax = mask, min, max
+
+	uids := e.uids
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	if len(uids) == 0 {
+		return
+	}
+
+	chunker := newCDCChunker()
+	start := 0
+	for i := 1; i < len(uids); i++ {
+		h := chunker.push(uids[i] - uids[i-1])
+		size := uint32(i - start)
+		// A block's per-uid offsets are stored as uint32, so check the span *including* uids[i]
+		// before letting it join the pending block: if that would overflow, we must cut right
+		// now (leaving uids[i] to start the next block), not after the fact.
+		overflows := uids[i]-uids[start] > math.MaxUint32
+		if size < min && !overflows {
+			continue
+		}
+		if overflows || size >= max || h&mask == 0 {
+			emit(cdcBlock(uids[start:i]))
+			start = i
+		}
+	}
+	emit(cdcBlock(uids[start:]))
+}
+
+// cdcBlock builds a pb.UidBlock for a contiguous, sorted run of UIDs, storing each uid's offset
+// from the block's base in a roaring.Bitmap, exactly like the MSB scheme's per-block encoding.
+// NumUids is taken from the bitmap's cardinality, not len(uids): the MSB path gets uid
+// deduplication for free from roaring.Bitmap.Add treating its argument as a set, and since
+// Encoder.Add's CDC path has no equivalent dedup on its buffered e.uids slice, a duplicate uid
+// would otherwise leave NumUids overcounting what the bitmap can actually produce on decode.
+func cdcBlock(uids []uint64) *pb.UidBlock {
+	base := uids[0]
+	rb := roaring.New()
+	for _, u := range uids {
+		rb.Add(uint32(u - base))
+	}
+	encData, err := rb.ToBytes()
+	x.Check(err)
+	return &pb.UidBlock{Base: base, NumUids: uint32(rb.GetCardinality()), Deltas: encData}
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+// varyingDeltaUids generates n sorted uids with varied deltas, so the CDC rolling hash sees
+// enough entropy to cut blocks the way it would on real posting-list data rather than settling
+// into one fixed period.
+func varyingDeltaUids(n int) []uint64 {
+	r := rand.New(rand.NewSource(42))
+	uids := make([]uint64, n)
+	var cur uint64
+	for i := range uids {
+		cur += uint64(r.Intn(50) + 1)
+		uids[i] = cur
+	}
+	return uids
+}
+
+func cdcEncode(uids []uint64) *pb.UidPack {
+	enc := Encoder{
+		BlockSize: 100,
+		Boundary:  pb.UidPack_CDC,
+		rUids:     make(map[uint64]*roaring.Bitmap),
+	}
+	for _, u := range uids {
+		enc.Add(u)
+	}
+	return enc.Done()
+}
+
+func TestCDCRoundTrip(t *testing.T) {
+	uids := varyingDeltaUids(5000)
+	pack := cdcEncode(uids)
+	require.Equal(t, pb.UidPack_CDC, pack.Boundary)
+	require.True(t, len(pack.Blocks) > 1)
+
+	got := Decode(pack, 0)
+	require.Equal(t, uids, got)
+}
+
+// blockBases returns the Base of every block, used to compare two packs' block layouts.
+func blockBases(pack *pb.UidPack) []uint64 {
+	bases := make([]uint64, len(pack.Blocks))
+	for i, b := range pack.Blocks {
+		bases[i] = b.Base
+	}
+	return bases
+}
+
+// TestCDCLocalEditsBoundedRewrite shows that inserting a handful of UIDs near the tail of a large
+// sorted run only changes the few blocks around the edit, unlike the MSB scheme where a rewrite
+// near a 32-bit boundary can invalidate a block covering a large fraction of the data.
+func TestCDCLocalEditsBoundedRewrite(t *testing.T) {
+	const n = 20000
+	base := varyingDeltaUids(n)
+
+	before := cdcEncode(base)
+
+	edited := append([]uint64{}, base...)
+	// Insert a few uids near the tail.
+	insertAt := n - 5
+	extra := []uint64{base[insertAt] + 1, base[insertAt] + 2, base[insertAt] + 3}
+	edited = append(edited[:insertAt], append(extra, edited[insertAt:]...)...)
+
+	after := cdcEncode(edited)
+
+	// Count blocks present in one pack's layout but not the other, by Base. Content-defined
+	// cuts mean only the blocks whose rolling-hash window overlaps the edit should differ; a
+	// fixed-size MSB scheme sharing uids across one giant 32-bit-MSB block would instead see
+	// that entire block (potentially covering a large fraction of the data) change.
+	beforeSet := make(map[uint64]bool, len(before.Blocks))
+	for _, b := range blockBases(before) {
+		beforeSet[b] = true
+	}
+	afterSet := make(map[uint64]bool, len(after.Blocks))
+	for _, b := range blockBases(after) {
+		afterSet[b] = true
+	}
+
+	changed := 0
+	for b := range beforeSet {
+		if !afterSet[b] {
+			changed++
+		}
+	}
+	for b := range afterSet {
+		if !beforeSet[b] {
+			changed++
+		}
+	}
+
+	require.Less(t, changed, 10, "expected only a bounded number of blocks to differ, got %d changed out of %d/%d blocks",
+		changed, len(before.Blocks), len(after.Blocks))
+}
+
+// TestCDCHugeJumpDoesNotOverflowBlock guards against a block's span (last uid - base) exceeding
+// what fits in the uint32 roaring.Bitmap offsets used to store it. A single huge delta, if left
+// in the same block as the uids before it, would silently truncate on encode.
+func TestCDCHugeJumpDoesNotOverflowBlock(t *testing.T) {
+	uids := []uint64{1, 2, 3, 4, 5, 10, 10 + (1 << 33), 10 + (1 << 33) + 1, 10 + (1 << 33) + 2}
+	pack := cdcEncode(uids)
+
+	for _, b := range pack.Blocks {
+		if b.NumUids == 0 {
+			continue
+		}
+		last := b.Base
+		dec := NewDecoder(&pb.UidPack{Blocks: []*pb.UidBlock{b}})
+		for _, u := range dec.Seek(0, SeekStart) {
+			if u > last {
+				last = u
+			}
+		}
+		require.LessOrEqual(t, last-b.Base, uint64(math.MaxUint32),
+			"block base=%d spans past uint32, would have truncated offsets", b.Base)
+	}
+
+	got := Decode(pack, 0)
+	require.Equal(t, uids, got)
+}
+
+// TestCDCDuplicateUidDoesNotOvercountNumUids guards against NumUids exceeding what the block's
+// roaring.Bitmap can actually produce on decode, which previously happened when the same uid was
+// added twice: the MSB path dedups for free via roaring.Bitmap.Add, but the CDC path buffers raw
+// uids, so a duplicate must be reflected in NumUids via the bitmap's cardinality instead.
+func TestCDCDuplicateUidDoesNotOvercountNumUids(t *testing.T) {
+	uids := []uint64{1, 2, 2, 3, 4, 5}
+	pack := cdcEncode(uids)
+
+	for _, b := range pack.Blocks {
+		dec := NewDecoder(&pb.UidPack{Blocks: []*pb.UidBlock{b}})
+		require.Len(t, dec.Seek(0, SeekStart), int(b.NumUids))
+	}
+
+	require.Equal(t, []uint64{1, 2, 3, 4, 5}, Decode(pack, 0))
+}
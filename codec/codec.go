@@ -17,14 +17,26 @@
 package codec
 
 import (
+	"errors"
 	"math"
 	"sort"
+	"sync"
 
 	"github.com/RoaringBitmap/roaring"
 	"github.com/dgraph-io/dgraph/protos/pb"
 	"github.com/dgraph-io/dgraph/x"
 )
 
+// errIncompressible is returned by a Compressor when it declines to compress a block (e.g. lz4
+// on already-dense data). setBlocks treats it the same as an explicit pb.UidPack_None choice.
+var errIncompressible = errors.New("codec: block not compressible")
+
+// bufPool is used by Decoder.UnpackBlock to avoid allocating a fresh buffer for every
+// decompressed block. Buffers are resliced to zero length when taken from the pool.
+var bufPool = sync.Pool{
+	New: func() interface{} { return new([]byte) },
+}
+
 type seekPos int
 
 const (
@@ -42,12 +54,85 @@ var (
 // Encoder is used to convert a list of UIDs into a pb.UidPack object.
 type Encoder struct {
 	BlockSize int
-	pack      *pb.UidPack
-	uids      []uint64
-	rUids     map[uint64]*roaring.Bitmap
+	// Codec selects the compressor applied to each block's Deltas payload. The zero value,
+	// pb.UidPack_None, reproduces the historical behaviour of storing the raw roaring.Bitmap
+	// serialization, so existing callers don't need to change.
+	Codec pb.UidPack_Codec
+	// Boundary selects how UIDs are partitioned into blocks. The zero value, pb.UidPack_MSB,
+	// reproduces the historical fixed top-32-bits partitioning. pb.UidPack_CDC instead cuts
+	// blocks at content-defined boundaries; see cdc.go.
+	Boundary pb.UidPack_BoundaryMode
+	// CDCMask, CDCMin and CDCMax configure content-defined block cuts when Boundary is
+	// pb.UidPack_CDC; left at zero, each falls back to a package default. They're ignored
+	// otherwise.
+	CDCMask uint64
+	CDCMin  uint32
+	CDCMax  uint32
+	pack    *pb.UidPack
+	uids    []uint64
+	rUids   map[uint64]*roaring.Bitmap
 }
 
+// setBlocks builds e.pack.Blocks from whatever UIDs Add has accumulated, dispatching on
+// e.Boundary, and then applies e.Codec to each block's Deltas.
 func (e *Encoder) setBlocks() {
+	if e.pack == nil {
+		// Nothing was added; Done returns a nil pack.
+		return
+	}
+	c := e.compressor()
+	e.forEachBlock(func(block *pb.UidBlock) {
+		compressBlock(block, c, e.Codec)
+		e.pack.Blocks = append(e.pack.Blocks, block)
+	})
+	e.pack.Codec = e.Codec
+}
+
+// compressor resolves e.Codec to a Compressor, or nil for pb.UidPack_None.
+func (e *Encoder) compressor() Compressor {
+	if e.Codec == pb.UidPack_None {
+		return nil
+	}
+	c, err := getCodec(e.Codec)
+	x.Check(err)
+	return c
+}
+
+// forEachBlock builds blocks from whatever UIDs Add has accumulated, dispatching on e.Boundary,
+// and calls emit once per block in Base order. It's the single source of the block-partitioning
+// logic, shared by setBlocks (which keeps every block in e.pack.Blocks) and WriteTo (which writes
+// and discards each block as it's produced, so it never holds more than one in memory).
+func (e *Encoder) forEachBlock(emit func(*pb.UidBlock)) {
+	switch e.Boundary {
+	case pb.UidPack_CDC:
+		e.forEachBlockCDC(emit)
+	default:
+		e.forEachBlockMSB(emit)
+	}
+}
+
+// compressBlock runs block.Deltas (assumed to hold the raw roaring.Bitmap serialization) through
+// c, recording the chosen codec and original size on the block. A nil c, or an incompressible
+// block, leaves the block's Deltas as the raw bytes with Codec left at pb.UidPack_None.
+func compressBlock(block *pb.UidBlock, c Compressor, codec pb.UidPack_Codec) {
+	if c == nil {
+		return
+	}
+	raw := block.Deltas
+	compressed, err := c.Compress(nil, raw)
+	switch err {
+	case nil:
+		block.Codec = codec
+		block.OrigSize = uint32(len(raw))
+		block.Deltas = compressed
+	case errIncompressible:
+		// Leave block.Deltas as the raw bytes; block.Codec stays at its zero value.
+	default:
+		x.Check(err)
+	}
+}
+
+func (e *Encoder) forEachBlockMSB(emit func(*pb.UidBlock)) {
 	bases := make([]uint64, len(e.rUids))
 	i := 0
 	for base := range e.rUids {
@@ -61,15 +146,18 @@ func (e *Encoder) setBlocks() {
 		rb := e.rUids[base]
 		encData, err := rb.ToBytes()
 		x.Check(err)
-		block := &pb.UidBlock{Base: base, NumUids: uint32(rb.GetCardinality()), Deltas: encData}
-		e.pack.Blocks = append(e.pack.Blocks, block)
+		emit(&pb.UidBlock{Base: base, NumUids: uint32(rb.GetCardinality()), Deltas: encData})
 	}
 }
 
 // Add takes an uid and adds it to the list of UIDs to be encoded.
 func (e *Encoder) Add(uid uint64) {
 	if e.pack == nil {
-		e.pack = &pb.UidPack{BlockSize: uint32(e.BlockSize)}
+		e.pack = &pb.UidPack{BlockSize: uint32(e.BlockSize), Boundary: e.Boundary}
+	}
+	if e.Boundary == pb.UidPack_CDC {
+		e.uids = append(e.uids, uid)
+		return
 	}
 	msb := uid & msbBitMask
 	roaringInt := uint32(uid & (^msbBitMask))
@@ -115,7 +203,21 @@ func (d *Decoder) UnpackBlock() []uint64 {
 
 	block := d.Pack.Blocks[d.blockIdx]
 	rb := roaring.New()
-	x.Check2(rb.FromBuffer(block.Deltas))
+	if block.Codec == pb.UidPack_None {
+		x.Check2(rb.FromBuffer(block.Deltas))
+	} else {
+		// rb.FromBuffer reads the roaring bitmap directly out of the given slice without copying,
+		// so the pooled buffer must outlive the iteration below; it's only returned to the pool
+		// once we're done reading uids out of rb.
+		bufp := bufPool.Get().(*[]byte)
+		buf, err := decompressBlock(block, (*bufp)[:0])
+		x.Check(err)
+		x.Check2(rb.FromBuffer(buf))
+		defer func() {
+			*bufp = buf
+			bufPool.Put(bufp)
+		}()
+	}
 	rbIt := rb.Iterator()
 	for rbIt.HasNext() {
 		d.uids = append(d.uids, block.Base+uint64(rbIt.Next()))
@@ -125,6 +227,16 @@ func (d *Decoder) UnpackBlock() []uint64 {
 	return d.uids
 }
 
+// decompressBlock decompresses block.Deltas into dst using the codec recorded on the block,
+// returning the extended dst.
+func decompressBlock(block *pb.UidBlock, dst []byte) ([]byte, error) {
+	c, err := getCodec(block.Codec)
+	if err != nil {
+		return nil, err
+	}
+	return c.Decompress(dst, block.Deltas, int(block.OrigSize))
+}
+
 // ApproxLen returns the approximate number of UIDs in the pb.UidPack object.
 func (d *Decoder) ApproxLen() int {
 	return int(d.Pack.BlockSize) * (len(d.Pack.Blocks) - d.blockIdx)
@@ -302,12 +414,19 @@ func CopyUidPack(pack *pb.UidPack) *pb.UidPack {
 
 	packCopy := new(pb.UidPack)
 	packCopy.BlockSize = pack.BlockSize
+	packCopy.Codec = pack.Codec
+	packCopy.Boundary = pack.Boundary
+	packCopy.CdcMask = pack.CdcMask
+	packCopy.CdcMin = pack.CdcMin
+	packCopy.CdcMax = pack.CdcMax
 	packCopy.Blocks = make([]*pb.UidBlock, len(pack.Blocks))
 
 	for i, block := range pack.Blocks {
 		packCopy.Blocks[i] = new(pb.UidBlock)
 		packCopy.Blocks[i].Base = block.Base
 		packCopy.Blocks[i].NumUids = block.NumUids
+		packCopy.Blocks[i].Codec = block.Codec
+		packCopy.Blocks[i].OrigSize = block.OrigSize
 		packCopy.Blocks[i].Deltas = make([]byte, len(block.Deltas))
 		copy(packCopy.Blocks[i].Deltas, block.Deltas)
 	}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+// postingListUids generates n monotonically increasing uids with gaps modeled after real posting
+// lists: mostly small deltas (dense runs from bulk loads) with occasional large jumps (sparse
+// updates).
+func postingListUids(n int) []uint64 {
+	uids := make([]uint64, n)
+	r := rand.New(rand.NewSource(1))
+	var cur uint64
+	for i := 0; i < n; i++ {
+		if r.Intn(20) == 0 {
+			cur += uint64(r.Intn(1 << 20))
+		} else {
+			cur += uint64(r.Intn(8) + 1)
+		}
+		uids[i] = cur
+	}
+	return uids
+}
+
+func packSize(pack *pb.UidPack) int {
+	sz := 0
+	for _, b := range pack.Blocks {
+		sz += len(b.Deltas)
+	}
+	return sz
+}
+
+// BenchmarkEncodeCodecs reports, for a posting-list-shaped uid distribution, the encoded size
+// under each codec. Run with -bench=EncodeCodecs -benchtime=1x to see the ratios; this is
+// primarily a ratio report rather than a throughput benchmark, though b.N iterations still time
+// the encode.
+func BenchmarkEncodeCodecs(b *testing.B) {
+	uids := postingListUids(1e6)
+	rawSize := len(uids) * 8
+
+	codecs := []struct {
+		name  string
+		codec pb.UidPack_Codec
+	}{
+		{"None", pb.UidPack_None},
+		{"Zstd", pb.UidPack_Zstd},
+		{"Lz4", pb.UidPack_Lz4},
+	}
+
+	for _, tc := range codecs {
+		tc := tc
+		b.Run(tc.name, func(b *testing.B) {
+			var pack *pb.UidPack
+			for i := 0; i < b.N; i++ {
+				enc := Encoder{BlockSize: 256, Codec: tc.codec, rUids: make(map[uint64]*roaring.Bitmap)}
+				for _, u := range uids {
+					enc.Add(u)
+				}
+				pack = enc.Done()
+			}
+			if pack != nil {
+				b.ReportMetric(float64(packSize(pack))/float64(rawSize)*100, "pct-of-raw")
+			}
+		})
+	}
+}
+
+// BenchmarkEncodeZstdLevels reports the same pct-of-raw ratio as BenchmarkEncodeCodecs' Zstd case,
+// but across zstd's encoder levels instead of just the package-level default encoder used by
+// zstdCodec. Run with -bench=EncodeZstdLevels -benchtime=1x to compare ratios across levels.
+func BenchmarkEncodeZstdLevels(b *testing.B) {
+	uids := postingListUids(1e6)
+	rawSize := len(uids) * 8
+
+	none := Encoder{BlockSize: 256, Codec: pb.UidPack_None, rUids: make(map[uint64]*roaring.Bitmap)}
+	for _, u := range uids {
+		none.Add(u)
+	}
+	rawPack := none.Done()
+
+	levels := []struct {
+		name  string
+		level zstd.EncoderLevel
+	}{
+		{"Fastest", zstd.SpeedFastest},
+		{"Default", zstd.SpeedDefault},
+		{"BetterCompression", zstd.SpeedBetterCompression},
+		{"BestCompression", zstd.SpeedBestCompression},
+	}
+
+	for _, tc := range levels {
+		tc := tc
+		b.Run(tc.name, func(b *testing.B) {
+			enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(tc.level))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer enc.Close()
+
+			var compressedSize int
+			for i := 0; i < b.N; i++ {
+				compressedSize = 0
+				for _, block := range rawPack.Blocks {
+					compressedSize += len(enc.EncodeAll(block.Deltas, nil))
+				}
+			}
+			b.ReportMetric(float64(compressedSize)/float64(rawSize)*100, "pct-of-raw")
+		})
+	}
+}
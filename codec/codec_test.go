@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+func encodeWithCodec(t *testing.T, uids []uint64, codec pb.UidPack_Codec) *pb.UidPack {
+	enc := Encoder{BlockSize: 10, Codec: codec, rUids: make(map[uint64]*roaring.Bitmap)}
+	for _, u := range uids {
+		enc.Add(u)
+	}
+	return enc.Done()
+}
+
+func TestEncodeDecodeCodecs(t *testing.T) {
+	uids := []uint64{1, 2, 3, 5, 100, 10000000001, 10000000002}
+
+	for _, codec := range []pb.UidPack_Codec{pb.UidPack_None, pb.UidPack_Zstd, pb.UidPack_Lz4} {
+		pack := encodeWithCodec(t, uids, codec)
+		require.Equal(t, codec, pack.Codec)
+
+		dec := NewDecoder(pack)
+		var got []uint64
+		for block := dec.Seek(0, SeekStart); len(block) > 0; block = dec.Next() {
+			got = append(got, block...)
+		}
+		require.Equal(t, uids, got)
+	}
+}
+
+// TestLz4DecompressReusesDst ensures lz4Codec.Decompress writes into the caller's dst buffer when
+// it already has enough capacity, rather than always allocating a fresh one, since that's the
+// whole point of bufPool in Decoder.UnpackBlock.
+func TestLz4DecompressReusesDst(t *testing.T) {
+	orig := []byte("the quick brown fox jumps over the lazy dog, repeatedly, to get past lz4's incompressible check")
+	compressed, err := (lz4Codec{}).Compress(nil, orig)
+	require.NoError(t, err)
+
+	dst := make([]byte, 0, len(orig))
+	dstPtr := &dst[:1][0]
+
+	got, err := (lz4Codec{}).Decompress(dst, compressed, len(orig))
+	require.NoError(t, err)
+	require.Equal(t, orig, got)
+	require.Same(t, dstPtr, &got[:1][0], "Decompress should reuse dst's backing array, not allocate a new one")
+}
+
+// TestSmallBlockFallsBackToRawWhenZstdDoesntShrinkIt ensures a tiny block requested with Codec:
+// Zstd, where the frame overhead exceeds any savings, falls back to being stored raw exactly like
+// lz4 already does, instead of always paying that overhead.
+func TestSmallBlockFallsBackToRawWhenZstdDoesntShrinkIt(t *testing.T) {
+	pack := encodeWithCodec(t, []uint64{1, 2, 3}, pb.UidPack_Zstd)
+	for _, b := range pack.Blocks {
+		require.Equal(t, pb.UidPack_None, b.Codec, "tiny block should have fallen back to raw storage")
+		require.Zero(t, b.OrigSize)
+	}
+
+	dec := NewDecoder(pack)
+	got := dec.Seek(0, SeekStart)
+	require.Equal(t, []uint64{1, 2, 3}, got)
+}
+
+// TestDecodeLegacyUntaggedBlock ensures blocks written before this change, which leave Codec at
+// its zero value, are still decoded as raw roaring.Bitmap payloads.
+func TestDecodeLegacyUntaggedBlock(t *testing.T) {
+	pack := encodeWithCodec(t, []uint64{1, 2, 3}, pb.UidPack_None)
+	for _, b := range pack.Blocks {
+		require.Equal(t, pb.UidPack_None, b.Codec)
+		require.Zero(t, b.OrigSize)
+	}
+
+	dec := NewDecoder(pack)
+	got := dec.Seek(0, SeekStart)
+	require.Equal(t, []uint64{1, 2, 3}, got)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"github.com/pierrec/lz4"
+)
+
+// lz4Codec wraps pierrec/lz4's block format. Unlike zstd, lz4's block API requires the caller to
+// size the destination buffer up front, which is why Decompress relies on the origSize recorded
+// on the block.
+type lz4Codec struct{}
+
+func (lz4Codec) Compress(dst, src []byte) ([]byte, error) {
+	buf := make([]byte, lz4.CompressBlockBound(len(src)))
+	n, err := lz4.CompressBlock(src, buf, nil)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		// Incompressible input; lz4 declines to compress it. Fall back to storing it raw by
+		// reporting the caller should use pb.UidPack_None for this block instead.
+		return nil, errIncompressible
+	}
+	return append(dst, buf[:n]...), nil
+}
+
+func (lz4Codec) Decompress(dst, src []byte, origSize int) ([]byte, error) {
+	// lz4.UncompressBlock writes directly into its dst argument rather than appending, so reuse
+	// dst's spare capacity (e.g. from bufPool) when it's already big enough instead of always
+	// allocating a fresh buffer.
+	start := len(dst)
+	out := dst
+	if cap(out)-start < origSize {
+		out = make([]byte, start, start+origSize)
+		copy(out, dst)
+	}
+	n, err := lz4.UncompressBlock(src, out[start:start+origSize])
+	if err != nil {
+		return nil, err
+	}
+	return out[:start+n], nil
+}
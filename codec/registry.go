@@ -0,0 +1,62 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+// Compressor compresses and decompresses the Deltas payload of a single block. Implementations
+// must be safe for concurrent use.
+type Compressor interface {
+	// Compress appends the compressed form of src to dst and returns the result.
+	Compress(dst, src []byte) ([]byte, error)
+	// Decompress appends the decompressed form of src to dst and returns the result. origSize is
+	// the length of the uncompressed payload, as recorded on the block, and may be used as a hint
+	// to size dst.
+	Decompress(dst, src []byte, origSize int) ([]byte, error)
+}
+
+// registry maps a pb.UidPack_Codec to the Compressor that handles it. pb.UidPack_None is
+// never registered here; it's handled as a no-op by the Encoder/Decoder directly.
+var registry = struct {
+	sync.RWMutex
+	m map[pb.UidPack_Codec]Compressor
+}{m: make(map[pb.UidPack_Codec]Compressor)}
+
+// RegisterCodec makes a Compressor available for use by the Encoder (via Encoder.Codec) and the
+// Decoder (which picks whichever codec a block was written with). Registering the same codec id
+// twice overwrites the previous entry; this is typically only done in init() or in tests.
+func RegisterCodec(id pb.UidPack_Codec, c Compressor) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.m[id] = c
+}
+
+// getCodec looks up the Compressor for id, returning an error if none is registered.
+func getCodec(id pb.UidPack_Codec) (Compressor, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c, ok := registry.m[id]
+	if !ok {
+		return nil, fmt.Errorf("codec: no compressor registered for codec id %v", id)
+	}
+	return c, nil
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// SeqDecoder is the surface shared by Decoder and StreamDecoder, so query code that walks a
+// posting list doesn't need to know whether it's backed by an in-memory pb.UidPack or a
+// streaming ReaderAt.
+type SeqDecoder interface {
+	Seek(uid uint64, whence seekPos) []uint64
+	Next() []uint64
+	UnpackBlock() []uint64
+	PeekNextBase() uint64
+}
+
+var (
+	_ SeqDecoder = (*Decoder)(nil)
+	_ SeqDecoder = (*StreamDecoder)(nil)
+)
+
+// Stream layout written by Encoder.WriteTo and read by StreamDecoder:
+//
+//	[block0 record][block1 record]...[index entry 0][index entry 1]...[footer]
+//
+// Each block record is a small fixed header followed by that block's (possibly compressed)
+// Deltas bytes; each index entry locates one block record by byte offset; the footer locates the
+// index and records how many entries it holds.
+const (
+	blockRecordHeaderSize = 4 + 1 + 4 // NumUids uint32, Codec uint8, OrigSize uint32
+	streamIndexEntrySize  = 8 + 8 + 8 // Base, Offset, Length, all uint64
+	streamFooterSize      = 8 + 4 + 1 + 1 + 2
+	streamVersion         = 1
+)
+
+type streamIndexEntry struct {
+	base   uint64
+	offset uint64
+	length uint64
+}
+
+type streamFooter struct {
+	indexOffset uint64
+	blockCount  uint32
+	codec       pb.UidPack_Codec
+	version     uint8
+}
+
+// WriteTo serializes the UIDs accumulated so far (via Add) in the streaming layout above, writing
+// directly to w. Each block is compressed, written and then dropped by forEachBlock before the
+// next one is built, so unlike marshaling a pb.UidPack, WriteTo never holds more than one block's
+// encoded bytes in memory at a time; only the small fixed-size index entries accumulate across
+// the whole call.
+func (e *Encoder) WriteTo(w io.Writer) (int64, error) {
+	if e.pack == nil {
+		e.pack = &pb.UidPack{BlockSize: uint32(e.BlockSize), Boundary: e.Boundary}
+	}
+	c := e.compressor()
+
+	cw := &countingWriter{w: w}
+	var index []streamIndexEntry
+	var writeErr error
+	e.forEachBlock(func(block *pb.UidBlock) {
+		if writeErr != nil {
+			return
+		}
+		compressBlock(block, c, e.Codec)
+
+		start := cw.n
+		if err := writeBlockRecord(cw, block); err != nil {
+			writeErr = err
+			return
+		}
+		index = append(index, streamIndexEntry{base: block.Base, offset: uint64(start), length: uint64(cw.n - start)})
+	})
+	if writeErr != nil {
+		return cw.n, writeErr
+	}
+
+	indexOffset := cw.n
+	var entryBuf [streamIndexEntrySize]byte
+	for _, ent := range index {
+		putIndexEntry(entryBuf[:], ent)
+		if _, err := cw.Write(entryBuf[:]); err != nil {
+			return cw.n, err
+		}
+	}
+
+	var footerBuf [streamFooterSize]byte
+	putFooter(footerBuf[:], streamFooter{
+		indexOffset: uint64(indexOffset),
+		blockCount:  uint32(len(index)),
+		codec:       e.Codec,
+		version:     streamVersion,
+	})
+	if _, err := cw.Write(footerBuf[:]); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+func writeBlockRecord(w io.Writer, block *pb.UidBlock) error {
+	var hdr [blockRecordHeaderSize]byte
+	binary.LittleEndian.PutUint32(hdr[0:4], block.NumUids)
+	hdr[4] = uint8(block.Codec)
+	binary.LittleEndian.PutUint32(hdr[5:9], block.OrigSize)
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(block.Deltas)
+	return err
+}
+
+func putIndexEntry(dst []byte, e streamIndexEntry) {
+	binary.LittleEndian.PutUint64(dst[0:8], e.base)
+	binary.LittleEndian.PutUint64(dst[8:16], e.offset)
+	binary.LittleEndian.PutUint64(dst[16:24], e.length)
+}
+
+func getIndexEntry(src []byte) streamIndexEntry {
+	return streamIndexEntry{
+		base:   binary.LittleEndian.Uint64(src[0:8]),
+		offset: binary.LittleEndian.Uint64(src[8:16]),
+		length: binary.LittleEndian.Uint64(src[16:24]),
+	}
+}
+
+func putFooter(dst []byte, f streamFooter) {
+	binary.LittleEndian.PutUint64(dst[0:8], f.indexOffset)
+	binary.LittleEndian.PutUint32(dst[8:12], f.blockCount)
+	dst[12] = uint8(f.codec)
+	dst[13] = f.version
+	// dst[14:16] reserved for future use; left zeroed.
+}
+
+func getFooter(src []byte) streamFooter {
+	return streamFooter{
+		indexOffset: binary.LittleEndian.Uint64(src[0:8]),
+		blockCount:  binary.LittleEndian.Uint32(src[8:12]),
+		codec:       pb.UidPack_Codec(src[12]),
+		version:     src[13],
+	}
+}
+
+// countingWriter wraps an io.Writer, tracking how many bytes have been written so far so callers
+// can record byte offsets as they stream.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// StreamDecoder is a random-access decoder over a serialized UidPack held by an io.ReaderAt, for
+// posting lists too large to materialize in full. Construction only reads the fixed-size footer
+// and the block index; Seek/Next/UnpackBlock read and decompress a single block's bytes at a
+// time.
+type StreamDecoder struct {
+	r        io.ReaderAt
+	index    []streamIndexEntry
+	blockIdx int
+	uids     []uint64
+}
+
+// NewStreamDecoder reads the footer and index at the end of r (which holds size bytes laid out
+// by Encoder.WriteTo) and returns a decoder positioned at the first block, which is not yet
+// decoded: call Seek or UnpackBlock to read it, as Next moves on to the second block.
+func NewStreamDecoder(r io.ReaderAt, size int64) (*StreamDecoder, error) {
+	if size < streamFooterSize {
+		return nil, fmt.Errorf("codec: stream of size %d too small to hold a footer", size)
+	}
+
+	var footerBuf [streamFooterSize]byte
+	if _, err := r.ReadAt(footerBuf[:], size-streamFooterSize); err != nil {
+		return nil, err
+	}
+	footer := getFooter(footerBuf[:])
+	if footer.version != streamVersion {
+		return nil, fmt.Errorf("codec: unsupported stream version %d", footer.version)
+	}
+
+	indexLen := int64(footer.blockCount) * streamIndexEntrySize
+	indexBuf := make([]byte, indexLen)
+	if indexLen > 0 {
+		if _, err := r.ReadAt(indexBuf, int64(footer.indexOffset)); err != nil {
+			return nil, err
+		}
+	}
+
+	index := make([]streamIndexEntry, footer.blockCount)
+	for i := range index {
+		index[i] = getIndexEntry(indexBuf[i*streamIndexEntrySize:])
+	}
+
+	return &StreamDecoder{r: r, index: index}, nil
+}
+
+// readBlock reads and decompresses the block at idx, returning its NumUids and the decoded
+// roaring.Bitmap bytes.
+func (d *StreamDecoder) readBlock(idx int) (base uint64, numUids uint32, rbBytes []byte, err error) {
+	ent := d.index[idx]
+	buf := make([]byte, ent.length)
+	if _, err := d.r.ReadAt(buf, int64(ent.offset)); err != nil {
+		return 0, 0, nil, err
+	}
+
+	numUids = binary.LittleEndian.Uint32(buf[0:4])
+	codec := pb.UidPack_Codec(buf[4])
+	origSize := binary.LittleEndian.Uint32(buf[5:9])
+	payload := buf[blockRecordHeaderSize:]
+
+	if codec == pb.UidPack_None {
+		return ent.base, numUids, payload, nil
+	}
+	c, cerr := getCodec(codec)
+	if cerr != nil {
+		return 0, 0, nil, cerr
+	}
+	decompressed, cerr := c.Decompress(make([]byte, 0, origSize), payload, int(origSize))
+	if cerr != nil {
+		return 0, 0, nil, cerr
+	}
+	return ent.base, numUids, decompressed, nil
+}
+
+// UnpackBlock decodes and returns the uids of the block at the decoder's current position.
+func (d *StreamDecoder) UnpackBlock() []uint64 {
+	if len(d.uids) > 0 {
+		d.uids = d.uids[:0]
+	}
+	if d.blockIdx < 0 || d.blockIdx >= len(d.index) {
+		return d.uids
+	}
+
+	base, numUids, rbBytes, err := d.readBlock(d.blockIdx)
+	x.Check(err)
+
+	rb := roaring.New()
+	x.Check2(rb.FromBuffer(rbBytes))
+	rbIt := rb.Iterator()
+	for rbIt.HasNext() {
+		d.uids = append(d.uids, base+uint64(rbIt.Next()))
+	}
+	d.uids = d.uids[:numUids]
+	return d.uids
+}
+
+// Seek searches for uid using the specified whence position, exactly like Decoder.Seek: it
+// binary-searches the (already in-memory) index on Base to find the candidate block, then reads
+// and decompresses only that one block (and possibly the next) to produce results.
+func (d *StreamDecoder) Seek(uid uint64, whence seekPos) []uint64 {
+	d.blockIdx = 0
+	if len(d.index) == 0 {
+		return []uint64{}
+	}
+	if uid == 0 {
+		return d.UnpackBlock()
+	}
+
+	var f func(int) bool
+	switch whence {
+	case SeekStart:
+		f = func(i int) bool { return d.index[i].base >= uid }
+	case SeekCurrent:
+		f = func(i int) bool { return d.index[i].base > uid }
+	}
+
+	idx := sort.Search(len(d.index), f)
+	if idx == 0 {
+		return d.UnpackBlock()
+	}
+	if idx < len(d.index) && d.index[idx].base == uid {
+		d.blockIdx = idx
+		return d.UnpackBlock()
+	}
+
+	d.blockIdx = idx - 1
+	d.UnpackBlock()
+
+	var uf func(int) bool
+	switch whence {
+	case SeekStart:
+		uf = func(i int) bool { return d.uids[i] >= uid }
+	case SeekCurrent:
+		uf = func(i int) bool { return d.uids[i] > uid }
+	}
+	uidx := sort.Search(len(d.uids), uf)
+	if uidx < len(d.uids) {
+		d.uids = d.uids[uidx:]
+		return d.uids
+	}
+	return d.Next()
+}
+
+// Next moves the decoder on to the next block.
+func (d *StreamDecoder) Next() []uint64 {
+	d.blockIdx++
+	return d.UnpackBlock()
+}
+
+// PeekNextBase returns the base of the next block without advancing the decoder.
+func (d *StreamDecoder) PeekNextBase() uint64 {
+	bidx := d.blockIdx + 1
+	if bidx < len(d.index) {
+		return d.index[bidx].base
+	}
+	return math.MaxUint64
+}
+
+// Valid returns true if the decoder has not reached the end of the stream.
+func (d *StreamDecoder) Valid() bool {
+	return d.blockIdx < len(d.index)
+}
+
+// BlockIdx returns the index of the block that is currently being decoded.
+func (d *StreamDecoder) BlockIdx() int {
+	return d.blockIdx
+}
+
+// Uids returns all the uids produced by the most recent UnpackBlock/Seek/Next call. As with
+// Decoder, the backing array is owned by the StreamDecoder and is overwritten by the next call.
+func (d *StreamDecoder) Uids() []uint64 {
+	return d.uids
+}
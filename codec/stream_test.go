@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+func writeStream(t *testing.T, uids []uint64, codec pb.UidPack_Codec) *bytes.Reader {
+	enc := Encoder{BlockSize: 50, Codec: codec, rUids: make(map[uint64]*roaring.Bitmap)}
+	for _, u := range uids {
+		enc.Add(u)
+	}
+	var buf bytes.Buffer
+	_, err := enc.WriteTo(&buf)
+	require.NoError(t, err)
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestStreamDecoderMatchesDecoder(t *testing.T) {
+	uids := varyingDeltaUids(3000)
+
+	for _, codec := range []pb.UidPack_Codec{pb.UidPack_None, pb.UidPack_Zstd} {
+		r := writeStream(t, uids, codec)
+
+		sd, err := NewStreamDecoder(r, r.Size())
+		require.NoError(t, err)
+
+		var got []uint64
+		for block := sd.Seek(0, SeekStart); len(block) > 0; block = sd.Next() {
+			got = append(got, block...)
+		}
+		require.Equal(t, uids, got)
+	}
+}
+
+func TestStreamDecoderSeek(t *testing.T) {
+	uids := varyingDeltaUids(3000)
+	r := writeStream(t, uids, pb.UidPack_None)
+
+	sd, err := NewStreamDecoder(r, r.Size())
+	require.NoError(t, err)
+
+	mid := uids[len(uids)/2]
+	var fromStream []uint64
+	for block := sd.Seek(mid, SeekStart); len(block) > 0; block = sd.Next() {
+		fromStream = append(fromStream, block...)
+	}
+
+	pack := func() *pb.UidPack {
+		enc := Encoder{BlockSize: 50, rUids: make(map[uint64]*roaring.Bitmap)}
+		for _, u := range uids {
+			enc.Add(u)
+		}
+		return enc.Done()
+	}()
+	fromMem := Decode(pack, mid)
+
+	require.Equal(t, fromMem, fromStream)
+}
+
+// TestWriteToDoesNotMaterializeBlocks confirms WriteTo never accumulates blocks in e.pack.Blocks:
+// it streams each one straight to the writer via forEachBlock instead of going through setBlocks.
+func TestWriteToDoesNotMaterializeBlocks(t *testing.T) {
+	enc := Encoder{BlockSize: 50, rUids: make(map[uint64]*roaring.Bitmap)}
+	for _, u := range varyingDeltaUids(3000) {
+		enc.Add(u)
+	}
+
+	var buf bytes.Buffer
+	_, err := enc.WriteTo(&buf)
+	require.NoError(t, err)
+
+	require.Empty(t, enc.pack.Blocks, "WriteTo should stream blocks directly, not buffer them in pack.Blocks")
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2018 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	RegisterCodec(pb.UidPack_Zstd, zstdCodec{})
+	RegisterCodec(pb.UidPack_Lz4, lz4Codec{})
+}
+
+// zstdCodec wraps klauspost/compress/zstd at the default compression level. zstd.Encoder and
+// zstd.Decoder are themselves pooled internally by the library, so we keep a single package-level
+// instance of each rather than building one per block.
+type zstdCodec struct{}
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func (zstdCodec) Compress(dst, src []byte) ([]byte, error) {
+	out := zstdEncoder.EncodeAll(src, dst)
+	if len(out)-len(dst) >= len(src) {
+		// Frame overhead ate any savings (common for small blocks, e.g. a CDCMin-sized block of
+		// only 16 uids); fall back to storing the block raw, same as lz4Codec.Compress does.
+		return nil, errIncompressible
+	}
+	return out, nil
+}
+
+func (zstdCodec) Decompress(dst, src []byte, origSize int) ([]byte, error) {
+	return zstdDecoder.DecodeAll(src, dst)
+}
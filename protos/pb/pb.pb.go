@@ -0,0 +1,698 @@
+// Hand-written bindings for pb.proto, matching the wire format gogofaster's protoc-gen-gogo would
+// emit (Marshal/Unmarshal/Size, not just the struct+getters a plain generated-code stub would
+// have), since this snapshot has no protoc toolchain available to regenerate it for real. If this
+// package ever gains a proper `make regenerate` step, replace this file with real generator output
+// against pb.proto; until then, keep any further field additions here in sync with both the struct
+// tags and these hand-rolled codec methods.
+
+package pb
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// UidPack_Codec selects the compressor applied to a block's Deltas payload.
+type UidPack_Codec int32
+
+const (
+	UidPack_None UidPack_Codec = 0
+	UidPack_Zstd UidPack_Codec = 1
+	UidPack_Lz4  UidPack_Codec = 2
+)
+
+var UidPack_Codec_name = map[int32]string{
+	0: "None",
+	1: "Zstd",
+	2: "Lz4",
+}
+
+var UidPack_Codec_value = map[string]int32{
+	"None": 0,
+	"Zstd": 1,
+	"Lz4":  2,
+}
+
+func (x UidPack_Codec) String() string {
+	if s, ok := UidPack_Codec_name[int32(x)]; ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+
+// UidPack_BoundaryMode selects how an Encoder partitions UIDs into blocks.
+type UidPack_BoundaryMode int32
+
+const (
+	UidPack_MSB UidPack_BoundaryMode = 0
+	UidPack_CDC UidPack_BoundaryMode = 1
+)
+
+var UidPack_BoundaryMode_name = map[int32]string{
+	0: "MSB",
+	1: "CDC",
+}
+
+var UidPack_BoundaryMode_value = map[string]int32{
+	"MSB": 0,
+	"CDC": 1,
+}
+
+func (x UidPack_BoundaryMode) String() string {
+	if s, ok := UidPack_BoundaryMode_name[int32(x)]; ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+
+func init() {
+	proto.RegisterEnum("pb.UidPack_Codec", UidPack_Codec_name, UidPack_Codec_value)
+	proto.RegisterEnum("pb.UidPack_BoundaryMode", UidPack_BoundaryMode_name, UidPack_BoundaryMode_value)
+	proto.RegisterType((*UidPack)(nil), "pb.UidPack")
+	proto.RegisterType((*UidBlock)(nil), "pb.UidBlock")
+}
+
+// UidPack holds a list of UIDs packed into blocks, each separately encoded and optionally
+// compressed.
+type UidPack struct {
+	BlockSize uint32               `protobuf:"varint,1,opt,name=block_size,json=blockSize,proto3" json:"block_size,omitempty"`
+	Blocks    []*UidBlock          `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	Codec     UidPack_Codec        `protobuf:"varint,3,opt,name=codec,proto3,enum=pb.UidPack_Codec" json:"codec,omitempty"`
+	Boundary  UidPack_BoundaryMode `protobuf:"varint,4,opt,name=boundary,proto3,enum=pb.UidPack_BoundaryMode" json:"boundary,omitempty"`
+	CdcMask   uint64               `protobuf:"varint,5,opt,name=cdc_mask,json=cdcMask,proto3" json:"cdc_mask,omitempty"`
+	CdcMin    uint32               `protobuf:"varint,6,opt,name=cdc_min,json=cdcMin,proto3" json:"cdc_min,omitempty"`
+	CdcMax    uint32               `protobuf:"varint,7,opt,name=cdc_max,json=cdcMax,proto3" json:"cdc_max,omitempty"`
+}
+
+func (m *UidPack) Reset()         { *m = UidPack{} }
+func (m *UidPack) String() string { return fmt.Sprintf("%+v", *m) }
+func (*UidPack) ProtoMessage()    {}
+
+func (m *UidPack) GetBlockSize() uint32 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
+}
+
+func (m *UidPack) GetBlocks() []*UidBlock {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+func (m *UidPack) GetCodec() UidPack_Codec {
+	if m != nil {
+		return m.Codec
+	}
+	return UidPack_None
+}
+
+func (m *UidPack) GetBoundary() UidPack_BoundaryMode {
+	if m != nil {
+		return m.Boundary
+	}
+	return UidPack_MSB
+}
+
+func (m *UidPack) GetCdcMask() uint64 {
+	if m != nil {
+		return m.CdcMask
+	}
+	return 0
+}
+
+func (m *UidPack) GetCdcMin() uint32 {
+	if m != nil {
+		return m.CdcMin
+	}
+	return 0
+}
+
+func (m *UidPack) GetCdcMax() uint32 {
+	if m != nil {
+		return m.CdcMax
+	}
+	return 0
+}
+
+func (m *UidPack) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UidPack) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *UidPack) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.CdcMax != 0 {
+		i = encodeVarintPb(dAtA, i, uint64(m.CdcMax))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.CdcMin != 0 {
+		i = encodeVarintPb(dAtA, i, uint64(m.CdcMin))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.CdcMask != 0 {
+		i = encodeVarintPb(dAtA, i, m.CdcMask)
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Boundary != 0 {
+		i = encodeVarintPb(dAtA, i, uint64(m.Boundary))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Codec != 0 {
+		i = encodeVarintPb(dAtA, i, uint64(m.Codec))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Blocks) > 0 {
+		for iNdEx := len(m.Blocks) - 1; iNdEx >= 0; iNdEx-- {
+			size, err := m.Blocks[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintPb(dAtA, i, uint64(size))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.BlockSize != 0 {
+		i = encodeVarintPb(dAtA, i, uint64(m.BlockSize))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *UidPack) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.BlockSize != 0 {
+		n += 1 + sovPb(uint64(m.BlockSize))
+	}
+	if len(m.Blocks) > 0 {
+		for _, e := range m.Blocks {
+			l = e.Size()
+			n += 1 + l + sovPb(uint64(l))
+		}
+	}
+	if m.Codec != 0 {
+		n += 1 + sovPb(uint64(m.Codec))
+	}
+	if m.Boundary != 0 {
+		n += 1 + sovPb(uint64(m.Boundary))
+	}
+	if m.CdcMask != 0 {
+		n += 1 + sovPb(m.CdcMask)
+	}
+	if m.CdcMin != 0 {
+		n += 1 + sovPb(uint64(m.CdcMin))
+	}
+	if m.CdcMax != 0 {
+		n += 1 + sovPb(uint64(m.CdcMax))
+	}
+	return n
+}
+
+func (m *UidPack) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: UidPack: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: UidPack: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockSize", wireType)
+			}
+			m.BlockSize = 0
+			if err := readVarintInto(dAtA, &iNdEx, l, (*uint64)(nil), func(v uint64) { m.BlockSize = uint32(v) }); err != nil {
+				return err
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Blocks", wireType)
+			}
+			msgLen, err := readLength(dAtA, &iNdEx, l)
+			if err != nil {
+				return err
+			}
+			postIndex := iNdEx + msgLen
+			if postIndex < 0 || postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			block := &UidBlock{}
+			if err := block.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Blocks = append(m.Blocks, block)
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Codec", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.Codec = UidPack_Codec(v) }); err != nil {
+				return err
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Boundary", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.Boundary = UidPack_BoundaryMode(v) }); err != nil {
+				return err
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CdcMask", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.CdcMask = v }); err != nil {
+				return err
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CdcMin", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.CdcMin = uint32(v) }); err != nil {
+				return err
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CdcMax", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.CdcMax = uint32(v) }); err != nil {
+				return err
+			}
+		default:
+			skip, err := skipPb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skip < 0 || iNdEx+skip < 0 || iNdEx+skip > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skip
+		}
+		_ = preIndex
+	}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// UidBlock is a single block of a UidPack: a Base UID plus NumUids deltas from it, serialized
+// (and optionally compressed) into Deltas.
+type UidBlock struct {
+	Base     uint64        `protobuf:"varint,1,opt,name=base,proto3" json:"base,omitempty"`
+	NumUids  uint32        `protobuf:"varint,2,opt,name=num_uids,json=numUids,proto3" json:"num_uids,omitempty"`
+	Deltas   []byte        `protobuf:"bytes,3,opt,name=deltas,proto3" json:"deltas,omitempty"`
+	Codec    UidPack_Codec `protobuf:"varint,4,opt,name=codec,proto3,enum=pb.UidPack_Codec" json:"codec,omitempty"`
+	OrigSize uint32        `protobuf:"varint,5,opt,name=orig_size,json=origSize,proto3" json:"orig_size,omitempty"`
+}
+
+func (m *UidBlock) Reset()         { *m = UidBlock{} }
+func (m *UidBlock) String() string { return fmt.Sprintf("%+v", *m) }
+func (*UidBlock) ProtoMessage()    {}
+
+func (m *UidBlock) GetBase() uint64 {
+	if m != nil {
+		return m.Base
+	}
+	return 0
+}
+
+func (m *UidBlock) GetNumUids() uint32 {
+	if m != nil {
+		return m.NumUids
+	}
+	return 0
+}
+
+func (m *UidBlock) GetDeltas() []byte {
+	if m != nil {
+		return m.Deltas
+	}
+	return nil
+}
+
+func (m *UidBlock) GetCodec() UidPack_Codec {
+	if m != nil {
+		return m.Codec
+	}
+	return UidPack_None
+}
+
+func (m *UidBlock) GetOrigSize() uint32 {
+	if m != nil {
+		return m.OrigSize
+	}
+	return 0
+}
+
+func (m *UidBlock) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UidBlock) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *UidBlock) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.OrigSize != 0 {
+		i = encodeVarintPb(dAtA, i, uint64(m.OrigSize))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Codec != 0 {
+		i = encodeVarintPb(dAtA, i, uint64(m.Codec))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.Deltas) > 0 {
+		i -= len(m.Deltas)
+		copy(dAtA[i:], m.Deltas)
+		i = encodeVarintPb(dAtA, i, uint64(len(m.Deltas)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.NumUids != 0 {
+		i = encodeVarintPb(dAtA, i, uint64(m.NumUids))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Base != 0 {
+		i = encodeVarintPb(dAtA, i, m.Base)
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *UidBlock) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Base != 0 {
+		n += 1 + sovPb(m.Base)
+	}
+	if m.NumUids != 0 {
+		n += 1 + sovPb(uint64(m.NumUids))
+	}
+	if l = len(m.Deltas); l > 0 {
+		n += 1 + l + sovPb(uint64(l))
+	}
+	if m.Codec != 0 {
+		n += 1 + sovPb(uint64(m.Codec))
+	}
+	if m.OrigSize != 0 {
+		n += 1 + sovPb(uint64(m.OrigSize))
+	}
+	return n
+}
+
+func (m *UidBlock) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: UidBlock: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: UidBlock: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Base", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.Base = v }); err != nil {
+				return err
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NumUids", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.NumUids = uint32(v) }); err != nil {
+				return err
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Deltas", wireType)
+			}
+			byteLen, err := readLength(dAtA, &iNdEx, l)
+			if err != nil {
+				return err
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 || postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Deltas = append(m.Deltas[:0], dAtA[iNdEx:postIndex]...)
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Codec", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.Codec = UidPack_Codec(v) }); err != nil {
+				return err
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OrigSize", wireType)
+			}
+			if err := readVarintInto(dAtA, &iNdEx, l, nil, func(v uint64) { m.OrigSize = uint32(v) }); err != nil {
+				return err
+			}
+		default:
+			skip, err := skipPb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skip < 0 || iNdEx+skip < 0 || iNdEx+skip > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skip
+		}
+	}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// ErrIntOverflowPb and ErrInvalidLengthPb are returned by Unmarshal on malformed varints and
+// length-delimited fields, respectively, matching the sentinel errors gogofaster generates per
+// proto file.
+var (
+	ErrInvalidLengthPb = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowPb   = fmt.Errorf("proto: integer overflow")
+)
+
+// sovPb returns the number of bytes a varint encoding of x takes.
+func sovPb(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+
+// encodeVarintPb writes v as a varint ending just before offset in dAtA (callers build messages
+// back-to-front via MarshalToSizedBuffer) and returns the offset of the first byte written.
+func encodeVarintPb(dAtA []byte, offset int, v uint64) int {
+	offset -= sovPb(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+
+// readVarintInto decodes a varint starting at *iNdEx, advances *iNdEx past it, and passes the
+// decoded value to set.
+func readVarintInto(dAtA []byte, iNdEx *int, l int, _ *uint64, set func(uint64)) error {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		if shift >= 64 {
+			return ErrIntOverflowPb
+		}
+		if *iNdEx >= l {
+			return io.ErrUnexpectedEOF
+		}
+		b := dAtA[*iNdEx]
+		*iNdEx++
+		v |= uint64(b&0x7F) << shift
+		if b < 0x80 {
+			break
+		}
+	}
+	set(v)
+	return nil
+}
+
+// readLength decodes a varint length prefix starting at *iNdEx, advances *iNdEx past it, and
+// returns the decoded length.
+func readLength(dAtA []byte, iNdEx *int, l int) (int, error) {
+	var length int
+	if err := readVarintInto(dAtA, iNdEx, l, nil, func(v uint64) { length = int(v) }); err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, ErrInvalidLengthPb
+	}
+	return length, nil
+}
+
+// skipPb skips over a single field's value (of any wire type, including nested groups) and
+// returns the number of bytes consumed, for preserving unknown fields during Unmarshal.
+func skipPb(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowPb
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for {
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPb
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthPb
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, fmt.Errorf("proto: unexpected end of group")
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthPb
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}